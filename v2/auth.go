@@ -0,0 +1,211 @@
+package huego
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// AuthProvider wraps a Client's transport to inject credentials on every
+// request, and refreshes them on demand. It mirrors client-go's
+// rest/plugin.go AuthProvider, adapted for bridges that front the local
+// hue-application-key header with something else (OAuth2, a reverse proxy
+// bearer token, ...).
+type AuthProvider interface {
+	// WrapTransport wraps rt so that outgoing requests carry this
+	// provider's credentials.
+	WrapTransport(rt http.RoundTripper) http.RoundTripper
+	// Login refreshes the provider's credentials, e.g. exchanging a
+	// refresh token for a new access token.
+	Login() error
+}
+
+// AuthProviderFactory builds an AuthProvider from the config map passed via
+// Config.AuthProviderConfig.
+type AuthProviderFactory func(config map[string]string) (AuthProvider, error)
+
+var (
+	authProvidersMu sync.Mutex
+	authProviders   = map[string]AuthProviderFactory{}
+)
+
+// RegisterAuthProvider registers factory under name so it can be selected
+// with Config.AuthProviderName. Panics if name is already registered.
+func RegisterAuthProvider(name string, factory AuthProviderFactory) {
+	authProvidersMu.Lock()
+	defer authProvidersMu.Unlock()
+	if _, ok := authProviders[name]; ok {
+		panic(fmt.Sprintf("huego: auth provider %q is already registered", name))
+	}
+	authProviders[name] = factory
+}
+
+func getAuthProvider(name string, config map[string]string) (AuthProvider, error) {
+	authProvidersMu.Lock()
+	factory, ok := authProviders[name]
+	authProvidersMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("huego: no auth provider registered for name %q", name)
+	}
+	return factory(config)
+}
+
+func init() {
+	RegisterAuthProvider("application-key", newApplicationKeyAuthProvider)
+	RegisterAuthProvider("oauth2", newOAuth2AuthProvider)
+}
+
+// applicationKeyAuthProvider preserves huego's default behavior: sending a
+// static hue-application-key header on every request.
+type applicationKeyAuthProvider struct {
+	key string
+}
+
+func newApplicationKeyAuthProvider(config map[string]string) (AuthProvider, error) {
+	return &applicationKeyAuthProvider{key: config["username"]}, nil
+}
+
+func (p *applicationKeyAuthProvider) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &headerRoundTripper{rt: rt, header: "hue-application-key", value: p.key}
+}
+
+// Login is a no-op; the application key does not expire.
+func (p *applicationKeyAuthProvider) Login() error {
+	return nil
+}
+
+// headerRoundTripper injects a single static header on every request.
+type headerRoundTripper struct {
+	rt     http.RoundTripper
+	header string
+	value  string
+}
+
+func (h *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(h.header, h.value)
+	return h.rt.RoundTrip(req)
+}
+
+// oauth2AuthProvider exchanges a refresh token for an access token against
+// Philips' remote API and injects it as a Bearer token, refreshing once on
+// a 401. It is a stub: deployments with their own remote-access setup
+// should register a provider tailored to it instead.
+type oauth2AuthProvider struct {
+	mu           sync.Mutex
+	clientID     string
+	clientSecret string
+	refreshToken string
+	accessToken  string
+	tokenURL     string
+}
+
+func newOAuth2AuthProvider(config map[string]string) (AuthProvider, error) {
+	if config["refresh-token"] == "" {
+		return nil, fmt.Errorf("huego: oauth2 auth provider requires a refresh-token")
+	}
+	tokenURL := config["token-url"]
+	if tokenURL == "" {
+		tokenURL = "https://api.meethue.com/oauth2/refresh"
+	}
+	return &oauth2AuthProvider{
+		clientID:     config["client-id"],
+		clientSecret: config["client-secret"],
+		refreshToken: config["refresh-token"],
+		tokenURL:     tokenURL,
+	}, nil
+}
+
+func (p *oauth2AuthProvider) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &oauth2RoundTripper{provider: p, rt: rt}
+}
+
+// Login exchanges the current refresh token for a new access token.
+func (p *oauth2AuthProvider) Login() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {p.refreshToken},
+	}
+	req, err := http.NewRequest(http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.clientID, p.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("huego: oauth2 token refresh failed with status %s", res.Status)
+	}
+
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return err
+	}
+	p.accessToken = body.AccessToken
+	if body.RefreshToken != "" {
+		p.refreshToken = body.RefreshToken
+	}
+	return nil
+}
+
+func (p *oauth2AuthProvider) token() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.accessToken
+}
+
+// oauth2RoundTripper injects the current access token as a Bearer header
+// and retries once after a fresh Login when the bridge responds 401.
+type oauth2RoundTripper struct {
+	provider *oauth2AuthProvider
+	rt       http.RoundTripper
+}
+
+func (rt *oauth2RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rt.provider.token() == "" {
+		if err := rt.provider.Login(); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := rt.roundTripWithToken(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	res.Body.Close()
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	} else if req.Body != nil {
+		return nil, fmt.Errorf("huego: cannot retry request after 401: body is not rewindable")
+	}
+
+	if err := rt.provider.Login(); err != nil {
+		return nil, err
+	}
+	return rt.roundTripWithToken(req)
+}
+
+func (rt *oauth2RoundTripper) roundTripWithToken(req *http.Request) (*http.Response, error) {
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+rt.provider.token())
+	return rt.rt.RoundTrip(clone)
+}
@@ -0,0 +1,117 @@
+package huego
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRegisterAuthProvider_PanicsOnDuplicateName(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic when registering a duplicate provider name, got none")
+		}
+	}()
+	RegisterAuthProvider("application-key", newApplicationKeyAuthProvider)
+}
+
+func TestApplicationKeyAuthProvider_SetsHeader(t *testing.T) {
+	var gotHeader string
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		gotHeader = req.Header.Get("hue-application-key")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	p, err := newApplicationKeyAuthProvider(map[string]string{"username": "secret-key"})
+	if err != nil {
+		t.Fatalf("newApplicationKeyAuthProvider: %v", err)
+	}
+	wrapped := p.WrapTransport(rt)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid", nil)
+	if _, err := wrapped.RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if gotHeader != "secret-key" {
+		t.Fatalf("hue-application-key = %q, want %q", gotHeader, "secret-key")
+	}
+}
+
+func TestOAuth2AuthProvider_RetriesOnceAfter401(t *testing.T) {
+	var tokenRequests, apiRequests int
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		w.Write([]byte(`{"access_token":"token-` + strconv.Itoa(tokenRequests) + `","refresh_token":"refresh-2"}`))
+	}))
+	defer tokenSrv.Close()
+
+	p, err := newOAuth2AuthProvider(map[string]string{
+		"refresh-token": "refresh-1",
+		"token-url":     tokenSrv.URL,
+	})
+	if err != nil {
+		t.Fatalf("newOAuth2AuthProvider: %v", err)
+	}
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		apiRequests++
+		if req.Header.Get("Authorization") == "Bearer token-1" {
+			return &http.Response{StatusCode: http.StatusUnauthorized, Body: http.NoBody}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+
+	wrapped := p.WrapTransport(rt)
+	req, _ := http.NewRequest(http.MethodPut, "http://example.invalid", strings.NewReader(`{"on":true}`))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(`{"on":true}`)), nil
+	}
+
+	res, err := wrapped.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("final status = %d, want 200", res.StatusCode)
+	}
+	if apiRequests != 2 {
+		t.Fatalf("apiRequests = %d, want 2 (one 401 then one retry)", apiRequests)
+	}
+	if tokenRequests != 2 {
+		t.Fatalf("tokenRequests = %d, want 2 (initial login then re-login after 401)", tokenRequests)
+	}
+}
+
+func TestOAuth2AuthProvider_RejectsNonRewindableBodyAfter401(t *testing.T) {
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"token-1"}`))
+	}))
+	defer tokenSrv.Close()
+
+	p, err := newOAuth2AuthProvider(map[string]string{
+		"refresh-token": "refresh-1",
+		"token-url":     tokenSrv.URL,
+	})
+	if err != nil {
+		t.Fatalf("newOAuth2AuthProvider: %v", err)
+	}
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusUnauthorized, Body: http.NoBody}, nil
+	})
+	wrapped := p.WrapTransport(rt)
+	req, _ := http.NewRequest(http.MethodPut, "http://example.invalid", strings.NewReader(`{"on":true}`))
+	req.GetBody = nil
+
+	if _, err := wrapped.RoundTrip(req); err == nil {
+		t.Fatal("expected an error for a non-rewindable body after a 401, got nil")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
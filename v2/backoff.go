@@ -0,0 +1,125 @@
+package huego
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// BackoffManager decides how long a Request should wait before retrying a
+// call to a given URL. It is consulted by DoRaw before every attempt and
+// updated with the outcome of each one, the same role client-go's
+// urlBackoff plays for the Kubernetes REST client.
+type BackoffManager interface {
+	// UpdateBackoff records the outcome of a request to u. err is non-nil
+	// for transport-level failures; responseCode is the HTTP status of a
+	// completed response (0 if none was received).
+	UpdateBackoff(u *url.URL, err error, responseCode int)
+	// CalculateBackoff returns how long to wait before the next request to u.
+	CalculateBackoff(u *url.URL) time.Duration
+	// Sleep blocks for d, or returns immediately if d is zero.
+	Sleep(d time.Duration)
+}
+
+// NoBackoff is a BackoffManager that never delays a retry. Useful in tests;
+// DoRaw still retries 429/5xx responses up to the request's retry count.
+type NoBackoff struct{}
+
+// UpdateBackoff is a no-op.
+func (NoBackoff) UpdateBackoff(*url.URL, error, int) {}
+
+// CalculateBackoff always returns zero.
+func (NoBackoff) CalculateBackoff(*url.URL) time.Duration { return 0 }
+
+// Sleep blocks for d.
+func (NoBackoff) Sleep(d time.Duration) {
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+const (
+	backoffInitial = 1 * time.Second
+	backoffMax     = 32 * time.Second
+)
+
+// urlBackoff is the default BackoffManager. It tracks exponential backoff
+// per host+path-prefix: each 429/5xx doubles the delay up to backoffMax,
+// and each success halves it.
+type urlBackoff struct {
+	mu      sync.Mutex
+	current map[string]time.Duration
+}
+
+// NewBackoffManager returns the default per-host exponential BackoffManager,
+// starting at 1s and capping at 32s.
+func NewBackoffManager() BackoffManager {
+	return &urlBackoff{current: map[string]time.Duration{}}
+}
+
+// backoffKey groups requests by host and the first path segment so that,
+// for example, /clip/v2/... and /eventstream/clip/v2 on the same bridge
+// don't share a single backoff counter. All /clip/... paths on one bridge,
+// such as /clip/v2/resource/light and /clip/v2/resource/scene, share one.
+func backoffKey(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Host + "/" + firstPathSegment(u.Path)
+}
+
+func firstPathSegment(p string) string {
+	p = trimLeadingSlash(p)
+	for i := 0; i < len(p); i++ {
+		if p[i] == '/' {
+			return p[:i]
+		}
+	}
+	return p
+}
+
+func trimLeadingSlash(p string) string {
+	if len(p) > 0 && p[0] == '/' {
+		return p[1:]
+	}
+	return p
+}
+
+func (b *urlBackoff) UpdateBackoff(u *url.URL, err error, responseCode int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := backoffKey(u)
+	if err != nil || responseCode == http.StatusTooManyRequests || responseCode >= 500 {
+		cur := b.current[key]
+		if cur == 0 {
+			cur = backoffInitial
+		} else {
+			cur *= 2
+		}
+		if cur > backoffMax {
+			cur = backoffMax
+		}
+		b.current[key] = cur
+		return
+	}
+	if cur, ok := b.current[key]; ok && cur > 0 {
+		cur /= 2
+		if cur < time.Second {
+			cur = 0
+		}
+		b.current[key] = cur
+	}
+}
+
+func (b *urlBackoff) CalculateBackoff(u *url.URL) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.current[backoffKey(u)]
+}
+
+func (b *urlBackoff) Sleep(d time.Duration) {
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
@@ -0,0 +1,138 @@
+package huego
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestClient builds a Client against an httptest server, bypassing
+// NewClient's TLS/CA requirements since the server speaks plain HTTP.
+func newTestClient(t *testing.T, serverURL string) *Client {
+	t.Helper()
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		t.Fatalf("url.Parse(%q): %v", serverURL, err)
+	}
+	return &Client{
+		Client:  http.DefaultClient,
+		baseURL: u,
+		backoff: NoBackoff{},
+	}
+}
+
+func TestDoRaw_RetriesOnServiceUnavailable(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	body, err := NewRequest(c).Verb(http.MethodGet).Path("/foo").DoRaw(context.Background())
+	if err != nil {
+		t.Fatalf("DoRaw: %v", err)
+	}
+	if string(body) != `{"data":[]}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("attempts = %d, want 2", got)
+	}
+}
+
+func TestDoRaw_ResendsBodyOnRetry(t *testing.T) {
+	const payload = `{"hello":"world"}`
+	var attempts int32
+	var secondAttemptBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		secondAttemptBody = string(b)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	_, err := NewRequest(c).
+		Verb(http.MethodPut).
+		Path("/foo").
+		Body(strings.NewReader(payload)).
+		DoRaw(context.Background())
+	if err != nil {
+		t.Fatalf("DoRaw: %v", err)
+	}
+	if secondAttemptBody != payload {
+		t.Fatalf("retry resent body %q, want %q", secondAttemptBody, payload)
+	}
+}
+
+func TestDoRaw_ExhaustsRetries(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	_, err := NewRequest(c).Verb(http.MethodGet).Path("/foo").DoRaw(context.Background())
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if got := atomic.LoadInt32(&attempts); got != defaultMaxRetries+1 {
+		t.Fatalf("attempts = %d, want %d", got, defaultMaxRetries+1)
+	}
+}
+
+func TestDoRaw_MaxRetriesOverride(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	_, err := NewRequest(c).Verb(http.MethodGet).Path("/foo").MaxRetries(0).DoRaw(context.Background())
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("attempts = %d, want 1", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  int
+	}{
+		{"empty", "", 0},
+		{"seconds", "2", 2},
+		{"garbage", "not-a-date", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := retryAfter(tt.value)
+			if got.Seconds() != float64(tt.want) {
+				t.Fatalf("retryAfter(%q) = %v, want %d seconds", tt.value, got, tt.want)
+			}
+		})
+	}
+}
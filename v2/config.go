@@ -0,0 +1,217 @@
+package huego
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultCAData holds the PEM-encoded Signify root CA that signs every Hue
+// bridge's TLS leaf certificate. It ships empty in this tree, as the
+// certificate bytes are distributed as a separate vendored data file;
+// set it at init time (or populate TLSClientConfig.CAData/CAFile per
+// Config) before relying on certificate pinning.
+var DefaultCAData []byte
+
+// TLSClientConfig carries the settings NewClientForConfig uses to verify a
+// bridge's TLS certificate, modeled on client-go's rest.TLSClientConfig.
+type TLSClientConfig struct {
+	// Insecure skips certificate verification entirely. Prefer pinning
+	// CAData/CAFile instead; this exists for NewInsecureClient back-compat.
+	Insecure bool
+
+	// ServerName overrides the name used to verify the bridge's
+	// certificate, and is checked against the certificate's CommonName
+	// (the bridge id) when it is an IP address rather than a hostname.
+	ServerName string
+
+	// CAData is a PEM-encoded CA bundle appended to DefaultCAData.
+	CAData []byte
+
+	// CAFile is a path to a PEM-encoded CA bundle appended to DefaultCAData.
+	CAFile string
+}
+
+// Config holds the information needed to build a Client, modeled on
+// client-go's rest.Config.
+type Config struct {
+	// Host is the bridge's base URL or host:port pair.
+	Host string
+
+	// Username is the hue-application-key sent with every request.
+	Username string
+
+	TLSClientConfig TLSClientConfig
+
+	// Timeout is applied to the underlying http.Client. Zero means no
+	// timeout.
+	Timeout time.Duration
+
+	// Transport, if set, is used as-is instead of one built from
+	// TLSClientConfig.
+	Transport http.RoundTripper
+
+	// UserAgent is sent as the User-Agent header on every request.
+	UserAgent string
+
+	// QPS and Burst are reserved for client-side request rate limiting,
+	// mirroring rest.Config; huego does not yet implement a limiter.
+	QPS   float32
+	Burst int
+
+	// AuthProviderName selects a registered AuthProvider (see
+	// RegisterAuthProvider) to wrap the transport instead of the default
+	// hue-application-key header. AuthProviderConfig is passed to its
+	// factory. Leave both unset to keep today's Username-header behavior.
+	AuthProviderName   string
+	AuthProviderConfig map[string]string
+}
+
+// NewClientForConfig builds a Client from cfg, pinning the bridge's TLS
+// certificate against cfg.TLSClientConfig unless Insecure or Transport is
+// set. NewClient and NewInsecureClient are thin wrappers over this.
+func NewClientForConfig(cfg *Config) (*Client, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("host must be a URL or a host:port pair")
+	}
+
+	hostURL, err := url.Parse(cfg.Host)
+	if err != nil || hostURL.Scheme == "" || hostURL.Host == "" {
+		hostURL, err = url.Parse(fmt.Sprintf("https://%s", cfg.Host))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	transport := cfg.Transport
+	if transport == nil {
+		tlsConfig, err := buildTLSConfig(hostURL.Hostname(), cfg.TLSClientConfig)
+		if err != nil {
+			return nil, err
+		}
+		transport = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+			TLSClientConfig:       tlsConfig,
+		}
+	}
+
+	if cfg.AuthProviderName != "" {
+		provider, err := getAuthProvider(cfg.AuthProviderName, cfg.AuthProviderConfig)
+		if err != nil {
+			return nil, err
+		}
+		transport = provider.WrapTransport(transport)
+	}
+
+	return &Client{
+		Client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout,
+		},
+		baseURL:   hostURL,
+		username:  cfg.Username,
+		backoff:   NewBackoffManager(),
+		userAgent: cfg.UserAgent,
+	}, nil
+}
+
+// buildTLSConfig builds the tls.Config NewClientForConfig wires into its
+// Transport. host is the bridge's hostname or IP, used as the default
+// ServerName when tc.ServerName is unset.
+func buildTLSConfig(host string, tc TLSClientConfig) (*tls.Config, error) {
+	if tc.Insecure {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+
+	pool := x509.NewCertPool()
+	haveCA := false
+	if len(DefaultCAData) > 0 && pool.AppendCertsFromPEM(DefaultCAData) {
+		haveCA = true
+	}
+	if len(tc.CAData) > 0 && pool.AppendCertsFromPEM(tc.CAData) {
+		haveCA = true
+	}
+	if tc.CAFile != "" {
+		data, err := ioutil.ReadFile(tc.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("huego: reading CA file %s: %w", tc.CAFile, err)
+		}
+		if pool.AppendCertsFromPEM(data) {
+			haveCA = true
+		}
+	}
+
+	if !haveCA {
+		return nil, fmt.Errorf("huego: no CA material configured; set TLSClientConfig.CAData/CAFile (or populate DefaultCAData) to pin the bridge's certificate, or set TLSClientConfig.Insecure to skip verification")
+	}
+
+	cfg := &tls.Config{RootCAs: pool}
+
+	serverName := tc.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+
+	if net.ParseIP(serverName) != nil {
+		// The certificate's SAN never includes a bare IP, so skip the
+		// standard hostname check and verify the leaf's CommonName (the
+		// bridge id) against serverName ourselves.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyPeerCertificate = verifyBridgeCertificate(cfg.RootCAs, serverName)
+	} else {
+		cfg.ServerName = serverName
+	}
+
+	return cfg, nil
+}
+
+// verifyBridgeCertificate returns a VerifyPeerCertificate callback that
+// checks the presented leaf certificate's CommonName against expectedCN
+// (when set) and, if roots is non-nil, that the leaf chains up to it.
+func verifyBridgeCertificate(roots *x509.CertPool, expectedCN string) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("huego: bridge presented no certificates")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs[i] = cert
+		}
+
+		leaf := certs[0]
+		if expectedCN != "" && leaf.Subject.CommonName != expectedCN {
+			return fmt.Errorf("huego: bridge certificate CN %q does not match expected bridge id %q", leaf.Subject.CommonName, expectedCN)
+		}
+
+		if roots == nil {
+			return nil
+		}
+		intermediates := x509.NewCertPool()
+		for _, c := range certs[1:] {
+			intermediates.AddCert(c)
+		}
+		_, err := leaf.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		return err
+	}
+}
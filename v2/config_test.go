@@ -0,0 +1,160 @@
+package huego
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestBuildTLSConfig_Insecure(t *testing.T) {
+	cfg, err := buildTLSConfig("192.168.1.10", TLSClientConfig{Insecure: true})
+	if err != nil {
+		t.Fatalf("buildTLSConfig: %v", err)
+	}
+	if !cfg.InsecureSkipVerify {
+		t.Fatal("InsecureSkipVerify = false, want true")
+	}
+}
+
+// TestBuildTLSConfig_RequiresCAWhenNotInsecure locks in a deliberate,
+// fail-secure behavior change: since this tree ships DefaultCAData empty
+// (see its doc comment), buildTLSConfig refuses to silently fall back to
+// trusting any certificate. Callers hitting this from NewClient should set
+// DefaultCAData/TLSClientConfig.CAData or use NewInsecureClient.
+func TestBuildTLSConfig_RequiresCAWhenNotInsecure(t *testing.T) {
+	if _, err := buildTLSConfig("192.168.1.10", TLSClientConfig{}); err == nil {
+		t.Fatal("expected an error when no CA material is configured, got nil")
+	}
+}
+
+func TestBuildTLSConfig_HostnameUsesStandardVerification(t *testing.T) {
+	_, _, caPEM := generateTestCA(t)
+	cfg, err := buildTLSConfig("bridge.example.com", TLSClientConfig{CAData: caPEM})
+	if err != nil {
+		t.Fatalf("buildTLSConfig: %v", err)
+	}
+	if cfg.InsecureSkipVerify {
+		t.Fatal("InsecureSkipVerify = true for a hostname, want standard verification")
+	}
+	if cfg.ServerName != "bridge.example.com" {
+		t.Fatalf("ServerName = %q, want %q", cfg.ServerName, "bridge.example.com")
+	}
+	if cfg.RootCAs == nil {
+		t.Fatal("RootCAs is nil, want the configured CA pool")
+	}
+}
+
+// TestBuildTLSConfig_IPWithoutExplicitServerNameRejectsAnyCert reproduces the
+// bug where connecting by IP with no TLSClientConfig.ServerName set used to
+// accept any certificate, regardless of its CommonName.
+func TestBuildTLSConfig_IPWithoutExplicitServerNameRejectsAnyCert(t *testing.T) {
+	caCert, caKey, caPEM := generateTestCA(t)
+	leaf := generateTestLeaf(t, caCert, caKey, "001788fffe012345")
+
+	cfg, err := buildTLSConfig("192.168.1.10", TLSClientConfig{CAData: caPEM})
+	if err != nil {
+		t.Fatalf("buildTLSConfig: %v", err)
+	}
+	if !cfg.InsecureSkipVerify {
+		t.Fatal("InsecureSkipVerify = false when connecting by IP, want true (custom verification)")
+	}
+	if cfg.VerifyPeerCertificate == nil {
+		t.Fatal("VerifyPeerCertificate is nil when connecting by IP")
+	}
+	if err := cfg.VerifyPeerCertificate([][]byte{leaf}, nil); err == nil {
+		t.Fatal("VerifyPeerCertificate accepted a certificate whose CN doesn't match the dialed IP; want an error without an explicit ServerName")
+	}
+}
+
+func TestBuildTLSConfig_IPWithExplicitServerNameChecksCN(t *testing.T) {
+	caCert, caKey, caPEM := generateTestCA(t)
+	const expectedName = "10.0.0.5"
+	matching := generateTestLeaf(t, caCert, caKey, expectedName)
+	mismatched := generateTestLeaf(t, caCert, caKey, "10.0.0.9")
+
+	// Host and TLSClientConfig.ServerName can differ (e.g. a NAT'd or
+	// overridden address); ServerName is what gets checked.
+	cfg, err := buildTLSConfig("192.168.1.10", TLSClientConfig{CAData: caPEM, ServerName: expectedName})
+	if err != nil {
+		t.Fatalf("buildTLSConfig: %v", err)
+	}
+
+	if err := cfg.VerifyPeerCertificate([][]byte{matching}, nil); err != nil {
+		t.Fatalf("VerifyPeerCertificate rejected a certificate with the expected CN: %v", err)
+	}
+	if err := cfg.VerifyPeerCertificate([][]byte{mismatched}, nil); err == nil {
+		t.Fatal("VerifyPeerCertificate accepted a certificate with a mismatched CN")
+	}
+}
+
+func TestBuildTLSConfig_IPRejectsCertFromUntrustedCA(t *testing.T) {
+	_, _, caPEM := generateTestCA(t)
+	otherCA, otherKey, _ := generateTestCA(t)
+	const bridgeIP = "192.168.1.10"
+	untrusted := generateTestLeaf(t, otherCA, otherKey, bridgeIP)
+
+	cfg, err := buildTLSConfig(bridgeIP, TLSClientConfig{CAData: caPEM})
+	if err != nil {
+		t.Fatalf("buildTLSConfig: %v", err)
+	}
+	if err := cfg.VerifyPeerCertificate([][]byte{untrusted}, nil); err == nil {
+		t.Fatal("VerifyPeerCertificate accepted a certificate signed by an untrusted CA")
+	}
+}
+
+// generateTestCA returns a self-signed CA certificate and key, plus its PEM
+// encoding suitable for TLSClientConfig.CAData.
+func generateTestCA(t *testing.T) (*x509.Certificate, *rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("x509.ParseCertificate: %v", err)
+	}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return cert, key, caPEM
+}
+
+// generateTestLeaf returns the DER bytes of a certificate with the given
+// CommonName, signed by ca/caKey.
+func generateTestLeaf(t *testing.T, ca *x509.Certificate, caKey *rsa.PrivateKey, commonName string) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate: %v", err)
+	}
+	return der
+}
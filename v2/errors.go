@@ -0,0 +1,74 @@
+package huego
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// StatusError is returned for any bridge response outside the 2xx range. It
+// carries enough of the raw response to diagnose the failure, plus the
+// bridge's own error envelope when the body parses as CLIP-shaped JSON.
+type StatusError struct {
+	StatusCode   int
+	Status       string
+	URL          *url.URL
+	ResponseBody []byte
+	Errors       []APIError
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	if len(e.Errors) > 0 {
+		return fmt.Sprintf("huego: %s: %s", e.Status, e.Errors[0].Description)
+	}
+	return fmt.Sprintf("huego: %s", e.Status)
+}
+
+// newStatusError builds a StatusError from a non-2xx response, attempting
+// to parse body as a CLIP error envelope.
+func newStatusError(u *url.URL, statusCode int, status string, body []byte) *StatusError {
+	se := &StatusError{
+		StatusCode:   statusCode,
+		Status:       status,
+		URL:          u,
+		ResponseBody: body,
+	}
+	var envelope struct {
+		Errors []APIError `json:"errors"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		se.Errors = envelope.Errors
+	}
+	return se
+}
+
+// IsNotFound reports whether err is a StatusError for a 404 response.
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is a StatusError for a 401 response.
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized)
+}
+
+// IsRateLimited reports whether err is a StatusError for a 429 response.
+func IsRateLimited(err error) bool {
+	return hasStatusCode(err, http.StatusTooManyRequests)
+}
+
+// IsConflict reports whether err is a StatusError for a 409 response.
+func IsConflict(err error) bool {
+	return hasStatusCode(err, http.StatusConflict)
+}
+
+func hasStatusCode(err error, code int) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode == code
+	}
+	return false
+}
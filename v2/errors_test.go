@@ -0,0 +1,76 @@
+package huego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNewStatusError_ParsesCLIPEnvelope(t *testing.T) {
+	u, _ := url.Parse("https://bridge.example/clip/v2/resource/light/1")
+	body := []byte(`{"errors":[{"description":"light not found"}]}`)
+
+	err := newStatusError(u, http.StatusNotFound, "404 Not Found", body)
+
+	if err.StatusCode != http.StatusNotFound {
+		t.Fatalf("StatusCode = %d, want %d", err.StatusCode, http.StatusNotFound)
+	}
+	if len(err.Errors) != 1 || err.Errors[0].Description != "light not found" {
+		t.Fatalf("Errors = %+v, want one APIError with description %q", err.Errors, "light not found")
+	}
+	if err.Error() == "" {
+		t.Fatal("Error() returned an empty string")
+	}
+}
+
+func TestStatusSentinels(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      int
+		checkFunc func(error) bool
+	}{
+		{"not found", http.StatusNotFound, IsNotFound},
+		{"unauthorized", http.StatusUnauthorized, IsUnauthorized},
+		{"rate limited", http.StatusTooManyRequests, IsRateLimited},
+		{"conflict", http.StatusConflict, IsConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newStatusError(nil, tt.code, http.StatusText(tt.code), nil)
+			if !tt.checkFunc(err) {
+				t.Fatalf("sentinel for %d returned false", tt.code)
+			}
+			wrapped := errors.New("wrapped")
+			if tt.checkFunc(wrapped) {
+				t.Fatal("sentinel matched a non-StatusError")
+			}
+		})
+	}
+}
+
+func TestDoRaw_NotFoundIsStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[{"description":"light/1 not found"}]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	_, err := NewRequest(c).Verb(http.MethodGet).Path("/foo").DoRaw(context.Background())
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if !IsNotFound(err) {
+		t.Fatalf("IsNotFound(%v) = false, want true", err)
+	}
+	var se *StatusError
+	if !errors.As(err, &se) {
+		t.Fatalf("errors.As failed to extract *StatusError from %v", err)
+	}
+	if len(se.Errors) != 1 || se.Errors[0].Description != "light/1 not found" {
+		t.Fatalf("Errors = %+v", se.Errors)
+	}
+}
@@ -0,0 +1,185 @@
+package huego
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+)
+
+// resourceTypes associates a CLIP resource type string (as used by
+// Request.Resource) with the Go type Get, List and Update decode it into.
+// It is the registry the generic helpers below check against, so a typo'd
+// or mismatched resourceType fails fast instead of silently decoding into
+// the wrong struct. Guarded by resourceTypesMu since Watch (watch.go) reads
+// it from a background goroutine that can run concurrently with a caller
+// registering new types.
+var (
+	resourceTypesMu sync.RWMutex
+	resourceTypes   = map[string]reflect.Type{}
+)
+
+func init() {
+	RegisterResourceType(TypeLight, Light{})
+}
+
+// RegisterResourceType associates resourceType with the Go type that Get,
+// List and Update should decode it into. zero is only used for its type;
+// its value is discarded. Call this once per resource, typically from an
+// init func, before using Get[T]/List[T]/Update[T] with that resource.
+func RegisterResourceType(resourceType string, zero interface{}) {
+	resourceTypesMu.Lock()
+	defer resourceTypesMu.Unlock()
+	resourceTypes[resourceType] = reflect.TypeOf(zero)
+}
+
+// isRegisteredResourceType reports whether resourceType has been registered
+// via RegisterResourceType.
+func isRegisteredResourceType(resourceType string) bool {
+	resourceTypesMu.RLock()
+	defer resourceTypesMu.RUnlock()
+	_, ok := resourceTypes[resourceType]
+	return ok
+}
+
+// checkResourceType verifies resourceType is registered and registered
+// against T, so Get[Scene](ctx, c, TypeLight, id) fails before making a
+// request instead of decoding a light into a Scene.
+func checkResourceType[T any](resourceType string) error {
+	resourceTypesMu.RLock()
+	want, ok := resourceTypes[resourceType]
+	resourceTypesMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("huego: unregistered resource type %q; call RegisterResourceType first", resourceType)
+	}
+	var zero T
+	got := reflect.TypeOf(zero)
+	if got != want {
+		return fmt.Errorf("huego: resource type %q is registered as %s, not %s", resourceType, want, got)
+	}
+	return nil
+}
+
+// Params reflects obj's exported fields with `url:"name"` tags into query
+// parameters on r, the role VersionedParams plays for client-go's Request.
+// Zero-valued fields are omitted, matching encoding/json's omitempty.
+func (r *Request) Params(obj interface{}) *Request {
+	if obj == nil {
+		return r
+	}
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return r
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		r.err = fmt.Errorf("huego: Params requires a struct, got %s", v.Kind())
+		return r
+	}
+
+	q, err := url.ParseQuery(r.query)
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("url")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.IsZero() {
+			continue
+		}
+		q.Set(tag, fmt.Sprintf("%v", fv.Interface()))
+	}
+
+	r.query = q.Encode()
+	return r
+}
+
+// Get fetches the resourceType resource identified by id and decodes it
+// into T.
+func Get[T any](ctx context.Context, c *Client, resourceType string, id string) (*T, error) {
+	if err := checkResourceType[T](resourceType); err != nil {
+		return nil, err
+	}
+	res, err := NewRequest(c).
+		Verb(http.MethodGet).
+		Resource(resourceType).
+		ID(id).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var items []*T
+	if err := res.Into(&items); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("huego: %s %s not found", resourceType, id)
+	}
+	return items[0], nil
+}
+
+// List fetches every resource of resourceType and decodes each into T.
+func List[T any](ctx context.Context, c *Client, resourceType string) ([]*T, error) {
+	if err := checkResourceType[T](resourceType); err != nil {
+		return nil, err
+	}
+	res, err := NewRequest(c).
+		Verb(http.MethodGet).
+		Resource(resourceType).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var items []*T
+	if err := res.Into(&items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Update PUTs patch to the resourceType resource identified by id and
+// decodes the bridge's resulting representation into T.
+func Update[T any](ctx context.Context, c *Client, resourceType string, id string, patch interface{}) (*T, error) {
+	if err := checkResourceType[T](resourceType); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	res, err := NewRequest(c).
+		Verb(http.MethodPut).
+		Resource(resourceType).
+		ID(id).
+		Body(bytes.NewReader(body)).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var items []*T
+	if err := res.Into(&items); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("huego: %s %s not found after update", resourceType, id)
+	}
+	return items[0], nil
+}
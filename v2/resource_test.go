@@ -0,0 +1,122 @@
+package huego
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const typeWidget = "widget"
+
+type widget struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func init() {
+	RegisterResourceType(typeWidget, widget{})
+}
+
+func TestCheckResourceType_Unregistered(t *testing.T) {
+	err := checkResourceType[widget]("not-a-registered-type")
+	if err == nil {
+		t.Fatal("expected an error for an unregistered resource type, got nil")
+	}
+}
+
+func TestCheckResourceType_Mismatched(t *testing.T) {
+	type scene struct{ ID string }
+	err := checkResourceType[scene](typeWidget)
+	if err == nil {
+		t.Fatal("expected an error when T doesn't match the registered type, got nil")
+	}
+}
+
+func TestCheckResourceType_Matched(t *testing.T) {
+	if err := checkResourceType[widget](typeWidget); err != nil {
+		t.Fatalf("checkResourceType: %v", err)
+	}
+}
+
+func TestParams_OmitsZeroFields(t *testing.T) {
+	type query struct {
+		Name string `url:"name"`
+		Skip string `url:"-"`
+		N    int    `url:"n"`
+	}
+	r := &Request{}
+	r.Params(query{Name: "kitchen"})
+	if r.err != nil {
+		t.Fatalf("Params: %v", r.err)
+	}
+	if r.query != "name=kitchen" {
+		t.Fatalf("query = %q, want %q", r.query, "name=kitchen")
+	}
+}
+
+func TestGet_DecodesFirstMatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/clip/v2/resource/widget/1" {
+			t.Errorf("path = %q, want %q", r.URL.Path, "/clip/v2/resource/widget/1")
+		}
+		w.Write([]byte(`{"data":[{"id":"1","name":"gadget"}]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	got, err := Get[widget](context.Background(), c, typeWidget, "1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.ID != "1" || got.Name != "gadget" {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestGet_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	if _, err := Get[widget](context.Background(), c, typeWidget, "missing"); err == nil {
+		t.Fatal("expected an error for an empty result set, got nil")
+	}
+}
+
+func TestList_DecodesAllItems(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[{"id":"1","name":"a"},{"id":"2","name":"b"}]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	items, err := List[widget](context.Background(), c, typeWidget)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("len(items) = %d, want 2", len(items))
+	}
+}
+
+func TestUpdate_DecodesResultingRepresentation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("method = %s, want PUT", r.Method)
+		}
+		w.Write([]byte(`{"data":[{"id":"1","name":"renamed"}]}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	got, err := Update[widget](context.Background(), c, typeWidget, "1", map[string]string{"name": "renamed"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got.Name != "renamed" {
+		t.Fatalf("Name = %q, want %q", got.Name, "renamed")
+	}
+}
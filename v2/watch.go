@@ -0,0 +1,237 @@
+package huego
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cfarsbot/huego/v2/watch"
+)
+
+// EventType describes the kind of change a watch Event represents.
+type EventType string
+
+const (
+	// EventAdded indicates a resource was created.
+	EventAdded EventType = "add"
+	// EventUpdated indicates a resource was changed.
+	EventUpdated EventType = "update"
+	// EventDeleted indicates a resource was removed.
+	EventDeleted EventType = "delete"
+)
+
+// Event is a single change notification decoded off of the bridge's
+// eventstream. Data holds the raw CLIP v2 payload; use Into to decode it
+// into the typed resource registered for ResourceType.
+type Event struct {
+	Type         EventType
+	ResourceType string
+	Data         json.RawMessage
+}
+
+// Into unmarshals the event's Data into obj.
+func (e *Event) Into(obj interface{}) error {
+	return json.Unmarshal(e.Data, obj)
+}
+
+// Watcher streams Events until Stop is called, after which ResultChan is
+// closed.
+type Watcher interface {
+	ResultChan() <-chan Event
+	Stop()
+}
+
+// Watch issues the request with an SSE Accept header and returns a Watcher
+// that decodes the bridge's eventstream into Events, reconnecting with
+// exponential backoff and a Last-Event-ID header on transient errors. Only
+// resources registered via RegisterResourceType (the same registry Get,
+// List and Update use) are surfaced as Events; if Resource was set to an
+// unregistered type, Watch returns an error instead of silently dropping
+// every event for that type.
+func (r *Request) Watch(ctx context.Context) (Watcher, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.resourceType != "" {
+		if !isRegisteredResourceType(r.resourceType) {
+			return nil, fmt.Errorf("huego: unregistered resource type %q; call RegisterResourceType first", r.resourceType)
+		}
+	}
+	r.Header("Accept", "text/event-stream")
+	r.Path("/eventstream/clip/v2")
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &streamWatcher{
+		ctx:     ctx,
+		cancel:  cancel,
+		req:     r,
+		result:  make(chan Event),
+		stopped: make(chan struct{}),
+	}
+	conn, err := w.connect("")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	go w.run(conn)
+	return w, nil
+}
+
+// WatchLights returns a Watcher whose ResultChan is already filtered down
+// to light resource events.
+func (c *Client) WatchLights(ctx context.Context) (Watcher, error) {
+	w, err := NewRequest(c).Resource(TypeLight).Watch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// streamWatcher implements Watcher over the bridge's SSE eventstream.
+type streamWatcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	req    *Request
+	result chan Event
+
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+func (w *streamWatcher) ResultChan() <-chan Event {
+	return w.result
+}
+
+// Stop cancels the watcher's context, unblocking any in-flight read on the
+// eventstream connection, and signals run to exit.
+func (w *streamWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopped)
+		w.cancel()
+	})
+}
+
+// connect issues the underlying HTTP request, replaying lastEventID when
+// reconnecting so the bridge can resume from where the stream left off.
+func (w *streamWatcher) connect(lastEventID string) (*http.Response, error) {
+	client := w.req.c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if lastEventID != "" {
+		w.req.Header("Last-Event-ID", lastEventID)
+	}
+	u := w.req.URL().String()
+	httpReq, err := http.NewRequestWithContext(w.ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header = w.req.headers
+	res, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		res.Body.Close()
+		return nil, fmt.Errorf("huego: watch request failed with status %s", res.Status)
+	}
+	return res, nil
+}
+
+// run decodes frames off of conn, reconnecting with exponential backoff
+// when the stream is interrupted, until Stop is called or the context is
+// done. Canceling w.ctx (done by Stop) interrupts a blocked dec.Decode by
+// aborting the underlying connection, the same mechanism http.Client uses
+// for any other in-flight request.
+func (w *streamWatcher) run(res *http.Response) {
+	defer close(w.result)
+	backoff := time.Second
+	const maxBackoff = 32 * time.Second
+	dec := watch.NewDecoder(res.Body)
+	for {
+		f, err := dec.Decode()
+		if err != nil {
+			res.Body.Close()
+			select {
+			case <-w.stopped:
+				return
+			case <-w.ctx.Done():
+				return
+			default:
+			}
+
+			lastEventID := dec.LastEventID()
+			var newRes *http.Response
+			for newRes == nil {
+				select {
+				case <-time.After(jitter(backoff)):
+				case <-w.stopped:
+					return
+				case <-w.ctx.Done():
+					return
+				}
+				backoff *= 2
+				if backoff > maxBackoff {
+					backoff = maxBackoff
+				}
+				// Only swap res/dec in once a reconnect actually succeeds;
+				// assigning a failed attempt's nil response into res would
+				// have the next loop iteration decode off, then close, a
+				// nil *http.Response.
+				newRes, err = w.connect(lastEventID)
+			}
+			res = newRes
+			dec = watch.NewDecoder(res.Body)
+			continue
+		}
+		backoff = time.Second
+
+		var typed struct {
+			Type string            `json:"type"`
+			Data []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(f.Data), &typed); err != nil {
+			continue
+		}
+		for _, d := range typed.Data {
+			var meta struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(d, &meta); err != nil {
+				continue
+			}
+			// Only resource types registered via RegisterResourceType (see
+			// resource.go) can be surfaced, since that registry is what lets
+			// callers know what Go type to decode Data into via Into.
+			if !isRegisteredResourceType(meta.Type) {
+				continue
+			}
+			// When Resource was set on the request, only that type is surfaced.
+			if w.req.resourceType != "" && meta.Type != w.req.resourceType {
+				continue
+			}
+			ev := Event{
+				Type:         EventType(typed.Type),
+				ResourceType: meta.Type,
+				Data:         d,
+			}
+			select {
+			case w.result <- ev:
+			case <-w.stopped:
+				return
+			case <-w.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// jitter adds up to 20% random jitter to d so that many reconnecting
+// watchers don't retry in lockstep.
+func jitter(d time.Duration) time.Duration {
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
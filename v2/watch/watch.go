@@ -0,0 +1,88 @@
+// Package watch decodes Server-Sent Events (SSE) streams into discrete
+// frames. It mirrors the role client-go's rest/watch package plays for
+// Kubernetes watch streams, but speaks SSE framing (`event:`, `data:`,
+// `id:`, blank-line terminators) instead of chunked JSON.
+package watch
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Frame is a single decoded SSE message. Data may span multiple `data:`
+// lines in the wire format; Decode concatenates them with "\n" per the SSE
+// spec.
+type Frame struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// Decoder reads SSE frames off of an underlying stream, keeping track of
+// the last seen id so callers can resume with a Last-Event-ID header.
+type Decoder struct {
+	r      *bufio.Reader
+	lastID string
+}
+
+// NewDecoder returns a Decoder that reads SSE frames from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// LastEventID returns the id of the most recently decoded frame, or the
+// empty string if no frame has carried one yet.
+func (d *Decoder) LastEventID() string {
+	return d.lastID
+}
+
+// Decode reads and returns the next complete SSE frame, blocking until the
+// terminating blank line arrives or the underlying reader errors. A frame
+// with no id, event or data lines is treated as a keep-alive and skipped.
+func (d *Decoder) Decode() (*Frame, error) {
+	for {
+		f := &Frame{}
+		var data []string
+		sawField := false
+		for {
+			line, err := d.r.ReadString('\n')
+			trimmed := strings.TrimRight(line, "\r\n")
+			if trimmed == "" {
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
+			if strings.HasPrefix(trimmed, ":") {
+				// Comment line, used by servers as a keep-alive.
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			switch {
+			case strings.HasPrefix(trimmed, "id:"):
+				sawField = true
+				f.ID = strings.TrimSpace(strings.TrimPrefix(trimmed, "id:"))
+			case strings.HasPrefix(trimmed, "event:"):
+				sawField = true
+				f.Event = strings.TrimSpace(strings.TrimPrefix(trimmed, "event:"))
+			case strings.HasPrefix(trimmed, "data:"):
+				sawField = true
+				data = append(data, strings.TrimPrefix(strings.TrimPrefix(trimmed, "data:"), " "))
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !sawField {
+			continue
+		}
+		f.Data = strings.Join(data, "\n")
+		if f.ID != "" {
+			d.lastID = f.ID
+		}
+		return f, nil
+	}
+}
@@ -0,0 +1,86 @@
+package huego
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatch_RequestsEventstreamEndpoint(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watcher, err := NewRequest(c).Resource(TypeLight).Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer watcher.Stop()
+
+	if gotPath != "/eventstream/clip/v2" {
+		t.Fatalf("request path = %q, want %q", gotPath, "/eventstream/clip/v2")
+	}
+}
+
+// TestWatch_SurvivesFailedReconnect reproduces a bug where a reconnect
+// attempt that fails to connect would leave the watcher decoding off of, and
+// then closing, a nil *http.Response on the next loop iteration. A panic in
+// the background goroutine here crashes the whole test binary; reaching the
+// end of this test is itself the assertion that the fix holds.
+func TestWatch_SurvivesFailedReconnect(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, "data: {\"type\":\"update\",\"data\":[{\"type\":\"light\"}]}\n\n")
+			return
+		}
+		// Every reconnect attempt fails, simulating a bridge returning 503s
+		// during firmware/zigbee activity.
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	watcher, err := NewRequest(c).Resource(TypeLight).Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer watcher.Stop()
+
+	select {
+	case ev, ok := <-watcher.ResultChan():
+		if !ok {
+			t.Fatal("ResultChan closed before delivering the first event")
+		}
+		if ev.ResourceType != "light" {
+			t.Fatalf("ResourceType = %q, want %q", ev.ResourceType, "light")
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("timed out waiting for the first event")
+	}
+
+	// Let the reconnect loop run through at least one failed attempt
+	// without panicking, then let the context timeout shut it down.
+	select {
+	case <-watcher.ResultChan():
+	case <-time.After(4 * time.Second):
+		t.Fatal("timed out waiting for ResultChan to close after context cancellation")
+	}
+}